@@ -46,35 +46,44 @@ type AnyTLS struct {
 
 type AnyTLSOption struct {
 	BasicOption
-	Name                        string                  `proxy:"name"`
-	Server                      string                  `proxy:"server"`
-	Port                        int                     `proxy:"port"`
-	Password                    string                  `proxy:"password"`
-	ALPN                        []string                `proxy:"alpn,omitempty"`
-	SNI                         string                  `proxy:"sni,omitempty"`
-	ECHOpts                     ECHOptions              `proxy:"ech-opts,omitempty"`
-	ClientFingerprint           string                  `proxy:"client-fingerprint,omitempty"`
-	SkipCertVerify              bool                    `proxy:"skip-cert-verify,omitempty"`
-	Fingerprint                 string                  `proxy:"fingerprint,omitempty"`
-	Certificate                 string                  `proxy:"certificate,omitempty"`
-	PrivateKey                  string                  `proxy:"private-key,omitempty"`
-	UDP                         bool                    `proxy:"udp,omitempty"`
-	IdleSessionCheckInterval    int                     `proxy:"idle-session-check-interval,omitempty"`
-	IdleSessionTimeout          int                     `proxy:"idle-session-timeout,omitempty"`
-	MinIdleSession              int                     `proxy:"min-idle-session,omitempty"`
-	SessionOverride             *AnyTLSSessionOverride  `proxy:"session-override,omitempty"` // Per-proxy overrides
+	Name                     string                 `proxy:"name"`
+	Server                   string                 `proxy:"server"`
+	Port                     int                    `proxy:"port"`
+	Password                 string                 `proxy:"password"`
+	ALPN                     []string               `proxy:"alpn,omitempty"`
+	SNI                      string                 `proxy:"sni,omitempty"`
+	ECHOpts                  ECHOptions             `proxy:"ech-opts,omitempty"`
+	ClientFingerprint        string                 `proxy:"client-fingerprint,omitempty"`
+	SkipCertVerify           bool                   `proxy:"skip-cert-verify,omitempty"`
+	Fingerprint              string                 `proxy:"fingerprint,omitempty"`
+	Certificate              string                 `proxy:"certificate,omitempty"`
+	PrivateKey               string                 `proxy:"private-key,omitempty"`
+	UDP                      bool                   `proxy:"udp,omitempty"`
+	IdleSessionCheckInterval int                    `proxy:"idle-session-check-interval,omitempty"`
+	IdleSessionTimeout       int                    `proxy:"idle-session-timeout,omitempty"`
+	MinIdleSession           int                    `proxy:"min-idle-session,omitempty"`
+	SessionOverride          *AnyTLSSessionOverride `proxy:"session-override,omitempty"` // Per-proxy overrides
 }
 
 // AnyTLSSessionOverride allows per-proxy overrides of global session management settings
 type AnyTLSSessionOverride struct {
-	EnsureIdleSession           *int `proxy:"ensure-idle-session,omitempty"`
-	MinIdleSession              *int `proxy:"min-idle-session,omitempty"`
-	MinIdleSessionForAge        *int `proxy:"min-idle-session-for-age,omitempty"`
-	EnsureIdleSessionCreateRate *int `proxy:"ensure-idle-session-create-rate,omitempty"`
-	MaxConnectionLifetime       *int `proxy:"max-connection-lifetime,omitempty"` // In seconds
-	ConnectionLifetimeJitter    *int `proxy:"connection-lifetime-jitter,omitempty"` // In seconds
-	IdleSessionTimeout          *int `proxy:"idle-session-timeout,omitempty"` // In seconds
-	IdleSessionCheckInterval    *int `proxy:"idle-session-check-interval,omitempty"` // In seconds
+	EnsureIdleSession           *int  `proxy:"ensure-idle-session,omitempty"`
+	MinIdleSession              *int  `proxy:"min-idle-session,omitempty"`
+	MinIdleSessionForAge        *int  `proxy:"min-idle-session-for-age,omitempty"`
+	EnsureIdleSessionCreateRate *int  `proxy:"ensure-idle-session-create-rate,omitempty"`
+	MaxConnectionLifetime       *int  `proxy:"max-connection-lifetime,omitempty"`     // In seconds
+	ConnectionLifetimeJitter    *int  `proxy:"connection-lifetime-jitter,omitempty"`  // In seconds
+	IdleSessionTimeout          *int  `proxy:"idle-session-timeout,omitempty"`        // In seconds
+	IdleSessionCheckInterval    *int  `proxy:"idle-session-check-interval,omitempty"` // In seconds
+	IdleHealthCheckInterval     *int  `proxy:"idle-health-check-interval,omitempty"`  // In seconds
+	IdleHealthCheckTimeout      *int  `proxy:"idle-health-check-timeout,omitempty"`   // In seconds
+	MigrationEnabled            *bool `proxy:"migration-enabled,omitempty"`
+	MigrationMaxAttempts        *int  `proxy:"migration-max-attempts,omitempty"`
+	MaxStreamsPerSession        *int  `proxy:"max-streams-per-session,omitempty"`
+	MaxStreamsPerDestination    *int  `proxy:"max-streams-per-destination,omitempty"`
+	AcquireTimeout              *int  `proxy:"acquire-timeout,omitempty"`   // In seconds
+	DataIdleTimeout             *int  `proxy:"data-idle-timeout,omitempty"` // In seconds
+	MinActiveSession            *int  `proxy:"min-active-session,omitempty"`
 }
 
 func (t *AnyTLS) DialContext(ctx context.Context, metadata *C.Metadata) (_ C.Conn, err error) {
@@ -106,10 +115,14 @@ func (t *AnyTLS) SupportUOT() bool {
 	return true
 }
 
-// ProxyInfo implements C.ProxyAdapter
+// ProxyInfo implements C.ProxyAdapter. It assumes C.ProxyInfo carries an
+// AnyTLSStats *C.AnyTLSStats field, mirrored in constant/anytls.go next to
+// AnyTLSSessionManagement.
 func (t *AnyTLS) ProxyInfo() C.ProxyInfo {
 	info := t.Base.ProxyInfo()
 	info.DialerProxy = t.option.DialerProxy
+	stats := t.Stats()
+	info.AnyTLSStats = &stats
 	return info
 }
 
@@ -118,6 +131,30 @@ func (t *AnyTLS) Close() error {
 	return t.client.Close()
 }
 
+// Stats reports a snapshot of this proxy's session pool telemetry (e.g. for
+// an API handler exposing per-proxy AnyTLS pool health). ProxyInfo carries
+// the same snapshot in its AnyTLSStats field.
+func (t *AnyTLS) Stats() C.AnyTLSStats {
+	s := t.client.Stats()
+	return C.AnyTLSStats{
+		IdleCount:               s.IdleCount,
+		ActiveCount:             s.ActiveCount,
+		ConsecutiveFailures:     s.ConsecutiveFailures,
+		Suspended:               s.Suspended,
+		SuspendedUntil:          s.SuspendedUntil,
+		LastError:               s.LastError,
+		TotalSessionsCreated:    s.TotalSessionsCreated,
+		ClosedIdle:              s.ClosedIdle,
+		ClosedAge:               s.ClosedAge,
+		ClosedHealth:            s.ClosedHealth,
+		ClosedExplicit:          s.ClosedExplicit,
+		ClosedDialFail:          s.ClosedDialFail,
+		ProactiveCreateSuccess:  s.ProactiveCreateSuccess,
+		ProactiveCreateFailure:  s.ProactiveCreateFailure,
+		DestinationStreamCounts: s.DestinationStreamCounts,
+	}
+}
+
 func NewAnyTLS(option AnyTLSOption) (*AnyTLS, error) {
 	addr := net.JoinHostPort(option.Server, strconv.Itoa(option.Port))
 	outbound := &AnyTLS{
@@ -156,6 +193,15 @@ func NewAnyTLS(option AnyTLSOption) (*AnyTLS, error) {
 		tOption.MinIdleSessionForAge = globalCfg.MinIdleSessionForAge
 		tOption.MaxConnectionLifetime = globalCfg.MaxConnectionLifetime
 		tOption.ConnectionLifetimeJitter = globalCfg.ConnectionLifetimeJitter
+		tOption.IdleHealthCheckInterval = globalCfg.IdleHealthCheckInterval
+		tOption.IdleHealthCheckTimeout = globalCfg.IdleHealthCheckTimeout
+		tOption.MigrationEnabled = globalCfg.MigrationEnabled
+		tOption.MigrationMaxAttempts = globalCfg.MigrationMaxAttempts
+		tOption.MaxStreamsPerSession = globalCfg.MaxStreamsPerSession
+		tOption.MaxStreamsPerDestination = globalCfg.MaxStreamsPerDestination
+		tOption.AcquireTimeout = globalCfg.AcquireTimeout
+		tOption.DataIdleTimeout = globalCfg.DataIdleTimeout
+		tOption.MinActiveSession = globalCfg.MinActiveSession
 	}
 
 	// Apply legacy per-proxy settings (backward compatibility)
@@ -196,6 +242,33 @@ func NewAnyTLS(option AnyTLSOption) (*AnyTLS, error) {
 		if override.IdleSessionCheckInterval != nil {
 			tOption.IdleSessionCheckInterval = time.Duration(*override.IdleSessionCheckInterval) * time.Second
 		}
+		if override.IdleHealthCheckInterval != nil {
+			tOption.IdleHealthCheckInterval = time.Duration(*override.IdleHealthCheckInterval) * time.Second
+		}
+		if override.IdleHealthCheckTimeout != nil {
+			tOption.IdleHealthCheckTimeout = time.Duration(*override.IdleHealthCheckTimeout) * time.Second
+		}
+		if override.MigrationEnabled != nil {
+			tOption.MigrationEnabled = *override.MigrationEnabled
+		}
+		if override.MigrationMaxAttempts != nil {
+			tOption.MigrationMaxAttempts = *override.MigrationMaxAttempts
+		}
+		if override.MaxStreamsPerSession != nil {
+			tOption.MaxStreamsPerSession = *override.MaxStreamsPerSession
+		}
+		if override.MaxStreamsPerDestination != nil {
+			tOption.MaxStreamsPerDestination = *override.MaxStreamsPerDestination
+		}
+		if override.AcquireTimeout != nil {
+			tOption.AcquireTimeout = time.Duration(*override.AcquireTimeout) * time.Second
+		}
+		if override.DataIdleTimeout != nil {
+			tOption.DataIdleTimeout = time.Duration(*override.DataIdleTimeout) * time.Second
+		}
+		if override.MinActiveSession != nil {
+			tOption.MinActiveSession = *override.MinActiveSession
+		}
 	}
 
 	echConfig, err := option.ECHOpts.Parse()
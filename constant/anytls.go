@@ -12,4 +12,37 @@ type AnyTLSSessionManagement struct {
 	ConnectionLifetimeJitter    time.Duration `yaml:"connection-lifetime-jitter"`      // Randomization range
 	IdleSessionTimeout          time.Duration `yaml:"idle-session-timeout"`            // Idle timeout
 	IdleSessionCheckInterval    time.Duration `yaml:"idle-session-check-interval"`     // Cleanup cycle interval
+	IdleHealthCheckInterval     time.Duration `yaml:"idle-health-check-interval"`      // Idle ping interval, 0 uses the 60s default
+	IdleHealthCheckTimeout      time.Duration `yaml:"idle-health-check-timeout"`       // Pong wait before marking unhealthy
+	MigrationEnabled            bool          `yaml:"migration-enabled"`               // Re-dial underlying transport on fatal read errors
+	MigrationMaxAttempts        int           `yaml:"migration-max-attempts"`          // Max re-dial attempts before giving up
+	MaxStreamsPerSession        int           `yaml:"max-streams-per-session"`         // 0 = unlimited
+	MaxStreamsPerDestination    int           `yaml:"max-streams-per-destination"`     // 0 = unlimited
+	AcquireTimeout              time.Duration `yaml:"acquire-timeout"`                 // Max wait for a destination slot
+	DataIdleTimeout             time.Duration `yaml:"data-idle-timeout"`               // Reap sessions quiet on the data plane, 0 disables
+	MinActiveSession            int           `yaml:"min-active-session"`              // Floor on sessions kept alive by DataIdleTimeout
+}
+
+// AnyTLSStats is a point-in-time snapshot of an AnyTLS session pool's
+// telemetry, carrying the counters of session.Stats for consumers outside
+// the transport package (e.g. the outbound adapter). The session-age and
+// stream-open-latency histograms of session.Stats are not carried.
+type AnyTLSStats struct {
+	IdleCount           int
+	ActiveCount         int
+	ConsecutiveFailures int32
+	Suspended           bool
+	SuspendedUntil      time.Time
+	LastError           string
+
+	TotalSessionsCreated   int64
+	ClosedIdle             int64
+	ClosedAge              int64
+	ClosedHealth           int64
+	ClosedExplicit         int64
+	ClosedDialFail         int64
+	ProactiveCreateSuccess int64
+	ProactiveCreateFailure int64
+
+	DestinationStreamCounts map[string]int
 }
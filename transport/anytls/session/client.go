@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -44,8 +45,56 @@ type Client struct {
 	// Age-based rotation (NEW)
 	maxConnectionLifetime    time.Duration
 	connectionLifetimeJitter time.Duration
+
+	// Failure backoff / circuit breaking for proactive session creation
+	consecutiveFailures atomic.Int32
+	suspendedUntil      atomic.Int64 // unix nano, 0 means not suspended
+	suspendedLogged     atomic.Bool
+	lastCreateErr       atomic.Pointer[error]
+
+	// Idle health probing (NEW)
+	idleHealthCheckInterval time.Duration
+	idleHealthCheckTimeout  time.Duration
+
+	// Session migration (NEW)
+	migrationEnabled     bool
+	migrationMaxAttempts int
+
+	// Per-destination concurrency limits and fairness (NEW)
+	maxStreamsPerSession     int
+	maxStreamsPerDestination int
+	acquireTimeout           time.Duration
+
+	destLock   sync.Mutex
+	destCond   *sync.Cond
+	destCounts map[string]int
+
+	// Activity-aware idle timeout (NEW): reaps sessions (and, within them,
+	// individual streams) that have gone quiet on the data plane, even if
+	// they're still checked out or haven't sat in the idle pool long enough
+	// to trip IdleSessionTimeout.
+	dataIdleTimeout  time.Duration
+	minActiveSession int
+
+	// Telemetry (NEW)
+	totalSessionsCreated   atomic.Int64
+	closedIdle             atomic.Int64
+	closedAge              atomic.Int64
+	closedHealth           atomic.Int64
+	closedExplicit         atomic.Int64
+	closedDialFail         atomic.Int64
+	proactiveCreateSuccess atomic.Int64
+	proactiveCreateFailure atomic.Int64
+	sessionAgeAtClose      *histogram
+	streamOpenLatency      *histogram
 }
 
+const (
+	createFailureBackoffBase = 2 * time.Second
+	createFailureBackoffCap  = 10 // 2s * 2^10 ~= 34m
+	createFailureJitter      = 0.2
+)
+
 // ClientConfig contains configuration for session client
 type ClientConfig struct {
 	IdleSessionCheckInterval    time.Duration
@@ -56,6 +105,15 @@ type ClientConfig struct {
 	MinIdleSessionForAge        int           // Age-based protection
 	MaxConnectionLifetime       time.Duration // Age-based rotation
 	ConnectionLifetimeJitter    time.Duration // Randomization
+	IdleHealthCheckInterval     time.Duration // Idle ping interval, 0 uses the 60s default
+	IdleHealthCheckTimeout      time.Duration // Pong wait before marking unhealthy
+	MigrationEnabled            bool          // Re-dial underlying transport on fatal read errors
+	MigrationMaxAttempts        int           // Max re-dial attempts before giving up
+	MaxStreamsPerSession        int           // 0 = unlimited
+	MaxStreamsPerDestination    int           // 0 = unlimited
+	AcquireTimeout              time.Duration // Max wait for a destination slot
+	DataIdleTimeout             time.Duration // 0 disables activity-aware reaping
+	MinActiveSession            int           // Floor on total sessions kept alive by DataIdleTimeout
 }
 
 func NewClient(ctx context.Context, dialOut util.DialOutFunc, _padding *atomic.Pointer[padding.PaddingFactory], config ClientConfig) *Client {
@@ -70,7 +128,20 @@ func NewClient(ctx context.Context, dialOut util.DialOutFunc, _padding *atomic.P
 		minIdleSessionForAge:        config.MinIdleSessionForAge,
 		maxConnectionLifetime:       config.MaxConnectionLifetime,
 		connectionLifetimeJitter:    config.ConnectionLifetimeJitter,
+		idleHealthCheckInterval:     config.IdleHealthCheckInterval,
+		idleHealthCheckTimeout:      config.IdleHealthCheckTimeout,
+		migrationEnabled:            config.MigrationEnabled,
+		migrationMaxAttempts:        config.MigrationMaxAttempts,
+		maxStreamsPerSession:        config.MaxStreamsPerSession,
+		maxStreamsPerDestination:    config.MaxStreamsPerDestination,
+		acquireTimeout:              config.AcquireTimeout,
+		dataIdleTimeout:             config.DataIdleTimeout,
+		minActiveSession:            config.MinActiveSession,
+		destCounts:                  make(map[string]int),
 	}
+	c.destCond = sync.NewCond(&c.destLock)
+	c.sessionAgeAtClose = newHistogram(sessionAgeBuckets)
+	c.streamOpenLatency = newHistogram(streamOpenLatencyBuckets)
 
 	// Set defaults
 	idleSessionCheckInterval := config.IdleSessionCheckInterval
@@ -80,6 +151,18 @@ func NewClient(ctx context.Context, dialOut util.DialOutFunc, _padding *atomic.P
 	if c.idleSessionTimeout <= time.Second*5 {
 		c.idleSessionTimeout = time.Second * 30
 	}
+	if c.idleHealthCheckInterval <= 0 {
+		c.idleHealthCheckInterval = time.Second * 60
+	}
+	if c.idleHealthCheckTimeout <= 0 {
+		c.idleHealthCheckTimeout = time.Second * 5
+	}
+	if c.migrationEnabled && c.migrationMaxAttempts <= 0 {
+		c.migrationMaxAttempts = 3
+	}
+	if c.maxStreamsPerDestination > 0 && c.acquireTimeout <= 0 {
+		c.acquireTimeout = time.Second * 10
+	}
 
 	c.die, c.dieCancel = context.WithCancel(ctx)
 	c.idleSession = skiplist.NewSkipList[uint64, *Session]()
@@ -87,31 +170,63 @@ func NewClient(ctx context.Context, dialOut util.DialOutFunc, _padding *atomic.P
 	return c
 }
 
-func (c *Client) CreateStream(ctx context.Context) (net.Conn, error) {
+// CreateProxy is the entry point callers dialing a specific proxy
+// destination should use: it stringifies destination (e.g. an
+// M.Socksaddr) and forwards to CreateStream, so the per-destination
+// concurrency limiting in acquireDestSlot/releaseDestSlot is actually
+// exercised on the live dial path rather than only in tests that call
+// CreateStream directly.
+func (c *Client) CreateProxy(ctx context.Context, destination fmt.Stringer) (net.Conn, error) {
+	return c.CreateStream(ctx, destination.String())
+}
+
+// CreateStream opens a Stream destined for hostport, fairly sharing sessions
+// and upstream capacity across destinations per MaxStreamsPerSession and
+// MaxStreamsPerDestination.
+func (c *Client) CreateStream(ctx context.Context, hostport string) (net.Conn, error) {
+	start := time.Now()
 	select {
 	case <-c.die.Done():
 		return nil, io.ErrClosedPipe
 	default:
 	}
 
+	if err := c.acquireDestSlot(ctx, hostport); err != nil {
+		return nil, fmt.Errorf("acquire destination slot for %s: %w", hostport, err)
+	}
+	releaseDest := func() { c.releaseDestSlot(hostport) }
+
 	var session *Session
 	var stream *Stream
 	var err error
 
 	session = c.getIdleSession()
 	if session == nil {
+		if until, ok := c.suspension(); ok {
+			// Pool maintenance is suspended and we have no idle session to fall
+			// back on: fail fast instead of dialing into a known-bad upstream.
+			releaseDest()
+			if lastErr := c.lastCreateErr.Load(); lastErr != nil {
+				return nil, fmt.Errorf("pool maintenance suspended until %s: %w", until.Format(time.RFC3339), *lastErr)
+			}
+			return nil, fmt.Errorf("pool maintenance suspended until %s", until.Format(time.RFC3339))
+		}
 		session, err = c.createSession(ctx)
 	}
 	if session == nil {
+		releaseDest()
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 	stream, err = session.OpenStream()
 	if err != nil {
+		releaseDest()
 		session.Close()
 		return nil, fmt.Errorf("failed to create stream: %w", err)
 	}
+	c.streamOpenLatency.observe(time.Since(start))
 
 	stream.dieHook = func() {
+		releaseDest()
 		// If Session is not closed, put this Stream to pool
 		if !session.IsClosed() {
 			select {
@@ -123,6 +238,7 @@ func (c *Client) CreateStream(ctx context.Context) (net.Conn, error) {
 				session.idleSince = time.Now()
 				c.idleSession.Insert(math.MaxUint64-session.seq, session)
 				c.idleSessionLock.Unlock()
+				session.startHealthCheck(c.idleHealthCheckInterval, c.idleHealthCheckTimeout)
 			}
 		}
 	}
@@ -130,27 +246,184 @@ func (c *Client) CreateStream(ctx context.Context) (net.Conn, error) {
 	return stream, nil
 }
 
+// getIdleSession pops a healthy session off the idle pool, preferring one
+// with spare stream capacity (below MaxStreamsPerSession/2) over the first
+// one found, and closing/skipping any that failed their last health probe.
 func (c *Client) getIdleSession() (idle *Session) {
+	halfCap := c.maxStreamsPerSession / 2
+
 	c.idleSessionLock.Lock()
-	if !c.idleSession.IsEmpty() {
-		it := c.idleSession.Iterate()
-		idle = it.Value()
-		c.idleSession.Remove(it.Key())
+	var dead []*Session
+	var deadKeys []uint64
+	var fallback *Session
+	var fallbackKey uint64
+	var chosenKey uint64
+
+	it := c.idleSession.Iterate()
+	for it.IsNotEnd() {
+		candidate := it.Value()
+		key := it.Key()
+
+		if !candidate.IsHealthy() {
+			dead = append(dead, candidate)
+			deadKeys = append(deadKeys, key)
+			it.MoveToNext()
+			continue
+		}
+
+		// With no per-session stream cap there's no fairness preference to
+		// scan for: take the first healthy candidate, same as the baseline
+		// single Iterate()/Remove() behavior.
+		if c.maxStreamsPerSession <= 0 {
+			idle, chosenKey = candidate, key
+			break
+		}
+
+		if fallback == nil {
+			fallback, fallbackKey = candidate, key
+		}
+		if candidate.StreamCount() < halfCap {
+			idle, chosenKey = candidate, key
+			break
+		}
+		it.MoveToNext()
+	}
+	if idle == nil {
+		idle, chosenKey = fallback, fallbackKey
+	}
+
+	for _, key := range deadKeys {
+		c.idleSession.Remove(key)
+	}
+	if idle != nil {
+		c.idleSession.Remove(chosenKey)
 	}
 	c.idleSessionLock.Unlock()
+
+	for _, session := range dead {
+		log.Debugln("[AnyTLS] Dropping idle session #%d that failed its health check", session.seq)
+		session.Close()
+	}
+
+	if idle != nil {
+		idle.stopHealthCheck()
+	}
 	return
 }
 
+// acquireDestSlot blocks until hostport has spare capacity under
+// MaxStreamsPerDestination, or returns context.DeadlineExceeded once
+// AcquireTimeout elapses. It is a no-op when MaxStreamsPerDestination is 0.
+func (c *Client) acquireDestSlot(ctx context.Context, hostport string) error {
+	if c.maxStreamsPerDestination <= 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(c.acquireTimeout)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		timer := time.NewTimer(c.acquireTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		case <-stop:
+			return
+		}
+		c.destLock.Lock()
+		c.destCond.Broadcast()
+		c.destLock.Unlock()
+	}()
+
+	c.destLock.Lock()
+	defer c.destLock.Unlock()
+	for c.destCounts[hostport] >= c.maxStreamsPerDestination {
+		if time.Now().After(deadline) {
+			return context.DeadlineExceeded
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.destCond.Wait()
+	}
+	c.destCounts[hostport]++
+	return nil
+}
+
+// releaseDestSlot frees a slot reserved by acquireDestSlot and wakes any
+// waiters for the same or other destinations.
+func (c *Client) releaseDestSlot(hostport string) {
+	if c.maxStreamsPerDestination <= 0 {
+		return
+	}
+	c.destLock.Lock()
+	if n := c.destCounts[hostport]; n <= 1 {
+		delete(c.destCounts, hostport)
+	} else {
+		c.destCounts[hostport] = n - 1
+	}
+	c.destCond.Broadcast()
+	c.destLock.Unlock()
+}
+
+// suspension reports whether proactive/on-demand session creation is
+// currently suspended due to repeated createSession failures.
+func (c *Client) suspension() (until time.Time, suspended bool) {
+	ts := c.suspendedUntil.Load()
+	if ts == 0 {
+		return time.Time{}, false
+	}
+	until = time.Unix(0, ts)
+	return until, time.Now().Before(until)
+}
+
+// recordCreateFailure increments the consecutive-failure counter and sets a
+// new suspension deadline following an exponential backoff with jitter,
+// mirroring suture's FailureThreshold/FailureBackoff supervisor pattern.
+func (c *Client) recordCreateFailure(err error) {
+	c.lastCreateErr.Store(&err)
+	failures := c.consecutiveFailures.Add(1)
+
+	exp := int(failures)
+	if exp > createFailureBackoffCap {
+		exp = createFailureBackoffCap
+	}
+	backoff := createFailureBackoffBase * time.Duration(uint64(1)<<uint(exp))
+	jitter := 1 + (rand.Float64()*2-1)*createFailureJitter
+	backoff = time.Duration(float64(backoff) * jitter)
+
+	until := time.Now().Add(backoff)
+	c.suspendedUntil.Store(until.UnixNano())
+	c.suspendedLogged.Store(false)
+}
+
+// recordCreateSuccess clears the failure streak and lifts any suspension.
+func (c *Client) recordCreateSuccess() {
+	c.consecutiveFailures.Store(0)
+	c.suspendedUntil.Store(0)
+	c.suspendedLogged.Store(false)
+}
+
 func (c *Client) createSession(ctx context.Context) (*Session, error) {
 	underlying, err := c.dialOut(ctx)
 	if err != nil {
+		c.recordCreateFailure(err)
+		c.closedDialFail.Add(1)
 		return nil, err
 	}
+	c.recordCreateSuccess()
+	c.totalSessionsCreated.Add(1)
 
 	session := NewClientSession(underlying, c.padding)
 	session.seq = c.sessionCounter.Add(1)
 	session.createdAt = time.Now() // Track creation time for age-based rotation
+	if c.migrationEnabled {
+		session.EnableMigration(c.dialOut, c.die, c.migrationMaxAttempts)
+	}
 	session.dieHook = func() {
+		c.sessionAgeAtClose.observe(time.Since(session.createdAt))
+
 		c.idleSessionLock.Lock()
 		c.idleSession.Remove(math.MaxUint64 - session.seq)
 		c.idleSessionLock.Unlock()
@@ -181,6 +454,7 @@ func (c *Client) Close() error {
 
 	for _, session := range sessionToClose {
 		session.Close()
+		c.closedExplicit.Add(1)
 	}
 
 	return nil
@@ -196,6 +470,7 @@ func (c *Client) cleanup() {
 
 	idleSessionsToClose := make([]*Session, 0)
 	ageSessionsToClose := make([]*Session, 0)
+	healthSessionsToClose := make([]*Session, 0)
 	idleActiveCount := 0
 	ageActiveCount := 0
 
@@ -208,6 +483,7 @@ func (c *Client) cleanup() {
 
 		shouldCloseIdle := false
 		shouldCloseAge := false
+		shouldCloseHealth := false
 
 		// Check idle timeout
 		if session.idleSince.Before(idleExpTime) {
@@ -242,12 +518,20 @@ func (c *Client) cleanup() {
 			}
 		}
 
-		// Close session if either condition met
-		if shouldCloseIdle || shouldCloseAge {
-			if shouldCloseIdle {
+		// Check idle health probe result
+		if !shouldCloseIdle && !shouldCloseAge && !session.IsHealthy() {
+			shouldCloseHealth = true
+		}
+
+		// Close session if any condition met
+		if shouldCloseIdle || shouldCloseAge || shouldCloseHealth {
+			switch {
+			case shouldCloseIdle:
 				idleSessionsToClose = append(idleSessionsToClose, session)
-			} else {
+			case shouldCloseAge:
 				ageSessionsToClose = append(ageSessionsToClose, session)
+			default:
+				healthSessionsToClose = append(healthSessionsToClose, session)
 			}
 			c.idleSession.Remove(key)
 		}
@@ -268,16 +552,77 @@ func (c *Client) cleanup() {
 			len(ageSessionsToClose), ageActiveCount)
 	}
 
+	// Debug logging for health check cleanup
+	if len(healthSessionsToClose) > 0 {
+		log.Debugln("[AnyTLS] Health cleanup: closing %d sessions that failed their idle health check",
+			len(healthSessionsToClose))
+	}
+
 	// Close sessions
 	for _, session := range idleSessionsToClose {
 		session.Close()
+		c.closedIdle.Add(1)
 	}
 	for _, session := range ageSessionsToClose {
 		session.Close()
+		c.closedAge.Add(1)
+	}
+	for _, session := range healthSessionsToClose {
+		session.Close()
+		c.closedHealth.Add(1)
+	}
+
+	// Activity-aware idle timeout (DataIdleTimeout): unlike idleSince, which
+	// only tracks time spent sitting in the pool, this looks at every
+	// session still known to the Client - idle or actively checked out -
+	// and reaps ones whose data plane has gone quiet. MinActiveSession
+	// guards against churning the whole pool during a quiet period.
+	if c.dataIdleTimeout > 0 {
+		c.sessionsLock.Lock()
+		allSessions := make([]*Session, 0, len(c.sessions))
+		for _, session := range c.sessions {
+			allSessions = append(allSessions, session)
+		}
+		c.sessionsLock.Unlock()
+
+		// Shed individually idle streams first; this also refreshes a
+		// session's own LastActivity via the FIN frames it writes, so a
+		// session with one dead stream among several busy ones survives.
+		for _, session := range allSessions {
+			session.pruneIdleStreams(c.dataIdleTimeout)
+		}
+
+		dataIdleExpTime := now.Add(-c.dataIdleTimeout)
+		dataIdleToClose := make([]*Session, 0)
+		remaining := len(allSessions)
+		for _, session := range allSessions {
+			if session.LastActivity().After(dataIdleExpTime) {
+				continue
+			}
+			if remaining <= c.minActiveSession {
+				continue
+			}
+			dataIdleToClose = append(dataIdleToClose, session)
+			remaining--
+		}
+
+		if len(dataIdleToClose) > 0 {
+			log.Debugln("[AnyTLS] Data-idle cleanup: closing %d sessions with no frame activity for %s (keeping %d protected)",
+				len(dataIdleToClose), c.dataIdleTimeout, c.minActiveSession)
+		}
+		for _, session := range dataIdleToClose {
+			session.Close()
+			c.closedIdle.Add(1)
+		}
 	}
 
 	// Proactive session creation (ensureIdleSession)
-	if c.ensureIdleSession > 0 {
+	if until, suspended := c.suspension(); suspended {
+		if !c.suspendedLogged.Swap(true) {
+			log.Debugln("[AnyTLS] pool maintenance suspended until %s (%d consecutive createSession failures)",
+				until.Format(time.RFC3339), c.consecutiveFailures.Load())
+		}
+	} else if c.ensureIdleSession > 0 {
 		deficit := c.ensureIdleSession - currentPoolSize
 		if deficit > 0 {
 			// Apply rate limiting
@@ -298,15 +643,18 @@ func (c *Client) cleanup() {
 
 					session, err := c.createSession(ctx)
 					if err != nil {
+						c.proactiveCreateFailure.Add(1)
 						log.Debugln("[AnyTLS] Failed to create proactive session: %v", err)
 						return
 					}
+					c.proactiveCreateSuccess.Add(1)
 
 					// Immediately put into idle pool
 					c.idleSessionLock.Lock()
 					session.idleSince = time.Now()
 					c.idleSession.Insert(math.MaxUint64-session.seq, session)
 					c.idleSessionLock.Unlock()
+					session.startHealthCheck(c.idleHealthCheckInterval, c.idleHealthCheckTimeout)
 
 					log.Debugln("[AnyTLS] Created proactive session #%d", session.seq)
 				}()
@@ -314,3 +662,53 @@ func (c *Client) cleanup() {
 		}
 	}
 }
+
+// PoolStats is a point-in-time snapshot of the session pool used by the
+// outbound layer to report health and make routing decisions.
+type PoolStats struct {
+	IdleCount           int
+	ConsecutiveFailures int32
+	Suspended           bool
+	SuspendedUntil      time.Time
+
+	// LastError is the message from the most recent createSession failure,
+	// if any. It's a string rather than an error so that JSON encoders
+	// (e.g. StatsHandler) serialize something readable instead of the
+	// unexported fields of whatever error type produced it.
+	LastError string
+
+	// DestinationStreamCounts is a snapshot of active streams per
+	// destination, keyed by hostport (see MaxStreamsPerDestination).
+	// Destinations are only tracked while MaxStreamsPerDestination is set;
+	// with no per-destination limit the map is empty.
+	DestinationStreamCounts map[string]int
+}
+
+// PoolStats reports the current state of the pool, including whether
+// proactive/on-demand session creation is suspended due to upstream failures.
+func (c *Client) PoolStats() PoolStats {
+	until, suspended := c.suspension()
+
+	c.idleSessionLock.Lock()
+	idleCount := c.idleSession.Len()
+	c.idleSessionLock.Unlock()
+
+	c.destLock.Lock()
+	destCounts := make(map[string]int, len(c.destCounts))
+	for k, v := range c.destCounts {
+		destCounts[k] = v
+	}
+	c.destLock.Unlock()
+
+	stats := PoolStats{
+		IdleCount:               idleCount,
+		ConsecutiveFailures:     c.consecutiveFailures.Load(),
+		Suspended:               suspended,
+		SuspendedUntil:          until,
+		DestinationStreamCounts: destCounts,
+	}
+	if lastErr := c.lastCreateErr.Load(); lastErr != nil {
+		stats.LastError = (*lastErr).Error()
+	}
+	return stats
+}
@@ -0,0 +1,114 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metacubex/mihomo/transport/anytls/padding"
+	"github.com/metacubex/mihomo/transport/anytls/util"
+)
+
+// newTestClient builds a Client with a long cleanup interval so the
+// background goroutine started by NewClient doesn't interfere with the
+// test, and registers it for cleanup.
+func newTestClient(t *testing.T, dialOut util.DialOutFunc, cfg ClientConfig) *Client {
+	if cfg.IdleSessionCheckInterval == 0 {
+		cfg.IdleSessionCheckInterval = time.Hour
+	}
+	if cfg.IdleSessionTimeout == 0 {
+		cfg.IdleSessionTimeout = time.Hour
+	}
+	c := NewClient(context.Background(), dialOut, new(atomic.Pointer[padding.PaddingFactory]), cfg)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestRecordCreateFailure_BackoffAndReset(t *testing.T) {
+	c := newTestClient(t, func(context.Context) (net.Conn, error) {
+		return nil, errors.New("dial failed")
+	}, ClientConfig{})
+
+	c.recordCreateFailure(errors.New("first"))
+	until1, suspended := c.suspension()
+	if !suspended {
+		t.Fatal("expected pool to be suspended after a failure")
+	}
+
+	c.recordCreateFailure(errors.New("second"))
+	until2, suspended := c.suspension()
+	if !suspended {
+		t.Fatal("expected pool to still be suspended after a second failure")
+	}
+	// Backoff is exponential in the failure count, so the worst-case (most
+	// jitter-shortened) deadline from the second failure must still exceed
+	// the best-case (most jitter-lengthened) deadline from the first.
+	if !until2.After(until1) {
+		t.Fatalf("expected suspension deadline to grow with consecutive failures: until1=%v until2=%v", until1, until2)
+	}
+	if got := c.consecutiveFailures.Load(); got != 2 {
+		t.Fatalf("expected consecutiveFailures=2, got %d", got)
+	}
+
+	c.recordCreateSuccess()
+	if _, suspended := c.suspension(); suspended {
+		t.Fatal("expected suspension to be cleared after a success")
+	}
+	if got := c.consecutiveFailures.Load(); got != 0 {
+		t.Fatalf("expected consecutiveFailures=0 after success, got %d", got)
+	}
+}
+
+func TestCreateStream_SuspendedFailsFast(t *testing.T) {
+	var dialCalls atomic.Int32
+	dialErr := errors.New("dial failed")
+	c := newTestClient(t, func(context.Context) (net.Conn, error) {
+		dialCalls.Add(1)
+		return nil, dialErr
+	}, ClientConfig{})
+
+	ctx := context.Background()
+	if _, err := c.CreateStream(ctx, "example.com:443"); err == nil {
+		t.Fatal("expected first CreateStream to fail")
+	}
+	if n := dialCalls.Load(); n != 1 {
+		t.Fatalf("expected exactly 1 dial attempt after the first failure, got %d", n)
+	}
+	if _, suspended := c.suspension(); !suspended {
+		t.Fatal("expected pool maintenance to be suspended after a createSession failure")
+	}
+
+	if _, err := c.CreateStream(ctx, "example.com:443"); err == nil {
+		t.Fatal("expected second CreateStream to fail")
+	}
+	if n := dialCalls.Load(); n != 1 {
+		t.Fatalf("expected suspended CreateStream to skip dialing entirely, dial count = %d", n)
+	}
+}
+
+func TestAcquireDestSlot_Timeout(t *testing.T) {
+	c := newTestClient(t, func(context.Context) (net.Conn, error) {
+		return nil, errors.New("unused")
+	}, ClientConfig{
+		MaxStreamsPerDestination: 1,
+		AcquireTimeout:           50 * time.Millisecond,
+	})
+
+	ctx := context.Background()
+	if err := c.acquireDestSlot(ctx, "example.com:443"); err != nil {
+		t.Fatalf("expected first acquireDestSlot to succeed, got %v", err)
+	}
+	defer c.releaseDestSlot("example.com:443")
+
+	start := time.Now()
+	err := c.acquireDestSlot(ctx, "example.com:443")
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded once the destination is full, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < c.acquireTimeout {
+		t.Fatalf("expected acquireDestSlot to wait out AcquireTimeout, only waited %v", elapsed)
+	}
+}
@@ -0,0 +1,171 @@
+package session
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/metacubex/mihomo/log"
+)
+
+// NOTE: this file implements only the client side of session migration.
+// The AnyTLS server's half (recognizing cmdMigrate, matching it to a
+// SessionGlobalID, replaying queued data for the advertised stream IDs, and
+// replying with cmdMigrateAck) lives in the AnyTLS server implementation,
+// not in mihomo's outbound transport - there is nothing to migrate *to* on
+// the client side of this package. Against a peer that doesn't implement
+// that half, resumeHandshake's capability check (capabilityFlag == 0) fails
+// the handshake outright, and reannounceStreams below is a best-effort
+// fallback for peers that accept cmdMigrate but don't retain per-stream
+// state across the swap.
+
+// migrate pauses stream I/O and attempts to re-dial the underlying transport,
+// resuming this Session on the new connection. It returns true if a
+// replacement connection was established (a new recvLoop has been started),
+// or false if all attempts failed or the Session's dial context was
+// canceled; migrate does not close the Session itself, the caller does.
+func (s *Session) migrate() bool {
+	s.migrateLock.Lock()
+	s.migrating = true
+	s.migrateLock.Unlock()
+
+attempts:
+	for attempt := 1; attempt <= s.migrationMaxAttempts; attempt++ {
+		conn, err := s.dialOut(s.dialCtx)
+		if err != nil {
+			log.Debugln("[AnyTLS] Session #%d migration attempt %d/%d dial failed: %v", s.seq, attempt, s.migrationMaxAttempts, err)
+			timer := time.NewTimer(time.Duration(attempt) * time.Second)
+			select {
+			case <-timer.C:
+				continue
+			case <-s.dialCtx.Done():
+				// Close() canceled dialCtx out from under us: stop retrying
+				// rather than burn through the remaining backoff/attempts.
+				timer.Stop()
+				break attempts
+			}
+		}
+
+		if err := s.resumeHandshake(conn); err != nil {
+			log.Debugln("[AnyTLS] Session #%d migration attempt %d/%d handshake failed: %v", s.seq, attempt, s.migrationMaxAttempts, err)
+			conn.Close()
+			continue
+		}
+
+		s.writeLock.Lock()
+		s.connPtr.Store(&conn)
+		s.writeLock.Unlock()
+
+		// The resume handshake only tells us whether the peer claims to
+		// support migration, not that it actually still holds state for
+		// each of our open Streams (it may be a different backend process
+		// entirely). Re-announce every open Stream on the new connection so
+		// the peer re-registers them instead of silently dropping cmdPSH
+		// frames for IDs it has never seen.
+		s.reannounceStreams()
+
+		s.migrateLock.Lock()
+		s.migrating = false
+		s.migrateCond.Broadcast()
+		s.migrateLock.Unlock()
+
+		log.Debugln("[AnyTLS] Session #%d migrated to a new connection after %d attempt(s)", s.seq, attempt)
+		go s.recvLoop()
+		return true
+	}
+
+	log.Debugln("[AnyTLS] Session #%d exhausted %d migration attempts, closing", s.seq, s.migrationMaxAttempts)
+	s.migrateLock.Lock()
+	s.migrating = false
+	s.migrateCond.Broadcast()
+	s.migrateLock.Unlock()
+	return false
+}
+
+// resumeHandshake performs an abbreviated handshake on conn, advertising this
+// Session's SessionGlobalID plus the IDs of every Stream still open on the
+// client side, so a cooperating peer can re-attach queued stream state
+// up front. It degrades gracefully if the peer doesn't support migration;
+// either way, reannounceStreams is still used after a successful handshake
+// since we have no protocol-level guarantee the peer actually retained
+// state for each ID (it may not be the same backend process at all).
+func (s *Session) resumeHandshake(conn io.ReadWriter) error {
+	s.streamsLock.Lock()
+	openIDs := make([]uint32, 0, len(s.streams))
+	for id := range s.streams {
+		openIDs = append(openIDs, id)
+	}
+	s.streamsLock.Unlock()
+
+	payload := make([]byte, len(s.globalID)+2+4*len(openIDs))
+	copy(payload, s.globalID[:])
+	binary.BigEndian.PutUint16(payload[len(s.globalID):], uint16(len(openIDs)))
+	offset := len(s.globalID) + 2
+	for _, id := range openIDs {
+		binary.BigEndian.PutUint32(payload[offset:], id)
+		offset += 4
+	}
+
+	header := make([]byte, frameHeaderSize+len(payload))
+	header[0] = cmdMigrate
+	binary.BigEndian.PutUint32(header[1:5], 0)
+	binary.BigEndian.PutUint16(header[5:7], uint16(len(payload)))
+	copy(header[frameHeaderSize:], payload)
+	if _, err := conn.Write(header); err != nil {
+		return fmt.Errorf("sending resume handshake: %w", err)
+	}
+
+	ack := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(conn, ack); err != nil {
+		return fmt.Errorf("reading resume handshake ack: %w", err)
+	}
+	if ack[0] != cmdMigrateAck {
+		return fmt.Errorf("unexpected response cmd=%d to resume handshake", ack[0])
+	}
+
+	length := binary.BigEndian.Uint16(ack[5:7])
+	capabilityFlag := byte(1)
+	if length > 0 {
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return fmt.Errorf("reading resume handshake payload: %w", err)
+		}
+		capabilityFlag = payload[0]
+	}
+	if capabilityFlag == 0 {
+		s.serverSupportsMigration.Store(false)
+		return fmt.Errorf("peer does not support session migration")
+	}
+	s.serverSupportsMigration.Store(true)
+	return nil
+}
+
+// reannounceStreams re-sends cmdSYN for every Stream still open on this
+// Session after a successful migration, so a peer that resumed us without
+// actually retaining per-stream state learns about them again instead of
+// silently dropping cmdPSH frames for IDs it has never seen.
+func (s *Session) reannounceStreams() {
+	s.streamsLock.Lock()
+	ids := make([]uint32, 0, len(s.streams))
+	for id := range s.streams {
+		ids = append(ids, id)
+	}
+	s.streamsLock.Unlock()
+
+	for _, id := range ids {
+		if err := s.writeFrame(cmdSYN, id, nil); err != nil {
+			log.Debugln("[AnyTLS] Session #%d failed to re-announce stream #%d after migration: %v", s.seq, id, err)
+		}
+	}
+}
+
+// waitIfMigrating blocks callers writing to the Session while a migration is
+// in progress, so in-flight Streams stall rather than error out.
+func (s *Session) waitIfMigrating() {
+	s.migrateLock.Lock()
+	for s.migrating {
+		s.migrateCond.Wait()
+	}
+	s.migrateLock.Unlock()
+}
@@ -0,0 +1,402 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/metacubex/mihomo/transport/anytls/padding"
+	"github.com/metacubex/mihomo/transport/anytls/util"
+)
+
+const (
+	cmdSYN           byte = iota + 1 // stream open
+	cmdPSH                           // data push
+	cmdFIN                           // stream close
+	cmdHeartRequest                  // ping
+	cmdHeartResponse                 // pong
+	cmdSessionID                     // advertise SessionGlobalID to the peer
+	cmdMigrate                       // resume a session on a new underlying connection
+	cmdMigrateAck                    // peer's response to cmdMigrate
+)
+
+const frameHeaderSize = 1 + 4 + 2 // cmd + streamID + length
+
+// isHeartbeatCmd reports whether cmd is a control-plane health probe rather
+// than data-plane traffic. Heartbeats must not refresh lastReadAt/
+// lastWriteAt, or a Session being actively health-checked while idle in the
+// pool would never look idle to DataIdleTimeout.
+func isHeartbeatCmd(cmd byte) bool {
+	return cmd == cmdHeartRequest || cmd == cmdHeartResponse
+}
+
+// Session multiplexes Streams over a single underlying AnyTLS connection.
+type Session struct {
+	// connPtr holds the current underlying connection. It's swapped out by
+	// migrate() while recvLoop, writeFrame, and Stream.LocalAddr/RemoteAddr
+	// may be reading it concurrently, so it's an atomic.Pointer rather than
+	// a plain field guarded by writeLock (which only serializes writers
+	// against each other, not against readers).
+	connPtr atomic.Pointer[net.Conn]
+	padding *atomic.Pointer[padding.PaddingFactory]
+
+	seq       uint64
+	createdAt time.Time
+	idleSince time.Time
+
+	// dieHook is invoked once the Session is closed, so the owning Client
+	// can drop it from its bookkeeping.
+	dieHook func()
+
+	writeLock sync.Mutex
+
+	streamsLock  sync.Mutex
+	streams      map[uint32]*Stream
+	nextStreamID atomic.Uint32
+
+	dieCh     chan struct{}
+	closed    atomic.Bool
+	closeOnce sync.Once
+
+	// Idle health probing (see IdleHealthCheckInterval/Timeout).
+	healthy     atomic.Bool
+	lastPongAt  atomic.Int64 // unix nano
+	probeLock   sync.Mutex
+	probeCancel context.CancelFunc
+
+	// Data-plane activity tracking (see DataIdleTimeout), updated on every
+	// frame read/written regardless of which Stream it belongs to, except
+	// heartbeat ping/pong frames (see isHeartbeatCmd) - those are generated
+	// by startHealthCheck's own polling and must not make an idle Session
+	// look active.
+	lastReadAt  atomic.Int64 // unix nano
+	lastWriteAt atomic.Int64 // unix nano
+
+	// Session migration: re-dialing the underlying transport on a fatal
+	// read error without tearing down open Streams.
+	globalID             [16]byte
+	migrationEnabled     bool
+	migrationMaxAttempts int
+	dialOut              util.DialOutFunc
+	// dialCtx is scoped to this Session, not the Client's die context, so a
+	// Close() during an in-progress migration cancels any in-flight re-dial
+	// immediately instead of letting it run through all migrationMaxAttempts.
+	dialCtx                 context.Context
+	dialCancel              context.CancelFunc
+	serverSupportsMigration atomic.Bool
+
+	migrateLock sync.Mutex
+	migrateCond *sync.Cond
+	migrating   bool
+
+	// streamCount tracks currently open Streams, used by the Client to
+	// prefer under-saturated sessions (see MaxStreamsPerSession).
+	streamCount atomic.Int32
+}
+
+// NewClientSession wraps a freshly dialed connection into a client-side
+// Session. The caller must still call Run to start processing frames.
+func NewClientSession(conn net.Conn, padding *atomic.Pointer[padding.PaddingFactory]) *Session {
+	s := &Session{
+		padding: padding,
+		streams: make(map[uint32]*Stream),
+		dieCh:   make(chan struct{}),
+	}
+	s.connPtr.Store(&conn)
+	s.healthy.Store(true)
+	s.lastPongAt.Store(time.Now().UnixNano())
+	now := time.Now().UnixNano()
+	s.lastReadAt.Store(now)
+	s.lastWriteAt.Store(now)
+	s.migrateCond = sync.NewCond(&s.migrateLock)
+	if _, err := rand.Read(s.globalID[:]); err != nil {
+		binary.BigEndian.PutUint64(s.globalID[:8], uint64(time.Now().UnixNano()))
+	}
+	return s
+}
+
+// EnableMigration turns on session migration: on a fatal read error, the
+// Session will re-dial dialOut and resume on the new connection instead of
+// tearing down its open Streams. Write errors do not trigger migration. maxAttempts bounds how many re-dials are
+// tried before giving up and closing the Session for good.
+func (s *Session) EnableMigration(dialOut util.DialOutFunc, ctx context.Context, maxAttempts int) {
+	s.migrationEnabled = true
+	s.migrationMaxAttempts = maxAttempts
+	s.dialOut = dialOut
+	s.dialCtx, s.dialCancel = context.WithCancel(ctx)
+}
+
+// GlobalID returns the 128-bit identifier this Session advertises to the
+// peer so it can be correlated across a migration.
+func (s *Session) GlobalID() [16]byte {
+	return s.globalID
+}
+
+// conn returns the Session's current underlying connection. migrate() swaps
+// it out concurrently with recvLoop, writeFrame, and Stream.LocalAddr/
+// RemoteAddr reading it, so all of those go through this accessor instead of
+// touching connPtr directly.
+func (s *Session) conn() net.Conn {
+	return *s.connPtr.Load()
+}
+
+// Run starts the frame receive loop in the background and, once enabled,
+// advertises this Session's SessionGlobalID to the peer.
+func (s *Session) Run() {
+	go s.recvLoop()
+	if s.migrationEnabled {
+		_ = s.writeFrame(cmdSessionID, 0, s.globalID[:])
+	}
+}
+
+func (s *Session) recvLoop() {
+	header := make([]byte, frameHeaderSize)
+	for {
+		conn := s.conn()
+		if _, err := io.ReadFull(conn, header); err != nil {
+			// Either migrate() started a replacement recvLoop, or the
+			// Session is now closed; either way this loop is done.
+			s.handleRecvError()
+			return
+		}
+		cmd := header[0]
+		streamID := binary.BigEndian.Uint32(header[1:5])
+		length := binary.BigEndian.Uint16(header[5:7])
+
+		var payload []byte
+		if length > 0 {
+			payload = make([]byte, length)
+			if _, err := io.ReadFull(conn, payload); err != nil {
+				// A frame truncated mid-payload leaves the peer's stream
+				// framing irrecoverable (we can't resync on the byte
+				// stream), so this is just as fatal as a header read
+				// error: attempt migration, or close.
+				s.handleRecvError()
+				return
+			}
+		}
+		if !isHeartbeatCmd(cmd) {
+			s.lastReadAt.Store(time.Now().UnixNano())
+		}
+
+		switch cmd {
+		case cmdPSH:
+			s.streamsLock.Lock()
+			stream := s.streams[streamID]
+			s.streamsLock.Unlock()
+			if stream != nil {
+				stream.pushData(payload)
+			}
+		case cmdFIN:
+			s.streamsLock.Lock()
+			stream := s.streams[streamID]
+			delete(s.streams, streamID)
+			s.streamsLock.Unlock()
+			if stream != nil {
+				stream.closeFromPeer()
+			}
+		case cmdHeartRequest:
+			_ = s.writeFrame(cmdHeartResponse, 0, nil)
+		case cmdHeartResponse:
+			s.lastPongAt.Store(time.Now().UnixNano())
+			s.healthy.Store(true)
+		}
+	}
+}
+
+// handleRecvError responds to a fatal read error on the current connection,
+// shared by both the header and payload read sites in recvLoop: it attempts
+// migration if enabled, falling back to closing the Session for good.
+func (s *Session) handleRecvError() {
+	if s.migrationEnabled && !s.IsClosed() && s.migrate() {
+		return
+	}
+	s.Close()
+}
+
+func (s *Session) writeFrame(cmd byte, streamID uint32, payload []byte) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	header := make([]byte, frameHeaderSize+len(payload))
+	header[0] = cmd
+	binary.BigEndian.PutUint32(header[1:5], streamID)
+	binary.BigEndian.PutUint16(header[5:7], uint16(len(payload)))
+	copy(header[frameHeaderSize:], payload)
+	_, err := s.conn().Write(header)
+	if err == nil && !isHeartbeatCmd(cmd) {
+		s.lastWriteAt.Store(time.Now().UnixNano())
+	}
+	return err
+}
+
+// LastActivity reports the most recent time this Session read or wrote a
+// frame, across all of its Streams and its control channel. Heartbeat
+// ping/pong frames are not counted (see isHeartbeatCmd).
+func (s *Session) LastActivity() time.Time {
+	last := s.lastReadAt.Load()
+	if w := s.lastWriteAt.Load(); w > last {
+		last = w
+	}
+	return time.Unix(0, last)
+}
+
+// pruneIdleStreams force-closes any open Stream that has seen no activity
+// for longer than timeout, so a handful of stuck streams don't keep an
+// otherwise-dead Session looking active. It returns the number closed.
+func (s *Session) pruneIdleStreams(timeout time.Duration) int {
+	if timeout <= 0 {
+		return 0
+	}
+	cutoff := time.Now().Add(-timeout)
+
+	s.streamsLock.Lock()
+	idle := make([]*Stream, 0)
+	for _, st := range s.streams {
+		if st.LastActivity().Before(cutoff) {
+			idle = append(idle, st)
+		}
+	}
+	s.streamsLock.Unlock()
+
+	for _, st := range idle {
+		st.Close()
+	}
+	return len(idle)
+}
+
+// OpenStream allocates a new Stream and advertises it to the peer.
+func (s *Session) OpenStream() (*Stream, error) {
+	if s.IsClosed() {
+		return nil, io.ErrClosedPipe
+	}
+	id := s.nextStreamID.Add(1)
+	stream := newStream(id, s)
+
+	s.streamsLock.Lock()
+	s.streams[id] = stream
+	s.streamsLock.Unlock()
+
+	if err := s.writeFrame(cmdSYN, id, nil); err != nil {
+		s.streamsLock.Lock()
+		delete(s.streams, id)
+		s.streamsLock.Unlock()
+		return nil, err
+	}
+	s.streamCount.Add(1)
+	return stream, nil
+}
+
+// StreamCount reports the number of currently open Streams on this Session.
+func (s *Session) StreamCount() int {
+	return int(s.streamCount.Load())
+}
+
+// decrementStreamCount is called exactly once per Stream as it tears down.
+func (s *Session) decrementStreamCount() {
+	s.streamCount.Add(-1)
+}
+
+func (s *Session) IsClosed() bool {
+	return s.closed.Load()
+}
+
+// Close tears down the Session, its underlying connection, any still-open
+// Streams, and stops idle health probing.
+func (s *Session) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.closed.Store(true)
+		close(s.dieCh)
+		s.stopHealthCheck()
+		if s.dialCancel != nil {
+			s.dialCancel()
+		}
+		err = s.conn().Close()
+
+		s.streamsLock.Lock()
+		streams := make([]*Stream, 0, len(s.streams))
+		for _, st := range s.streams {
+			streams = append(streams, st)
+		}
+		s.streams = make(map[uint32]*Stream)
+		s.streamsLock.Unlock()
+		for _, st := range streams {
+			st.closeFromPeer()
+		}
+
+		if s.dieHook != nil {
+			s.dieHook()
+		}
+	})
+	return err
+}
+
+// IsHealthy reports whether the last idle health probe, if any, succeeded.
+func (s *Session) IsHealthy() bool {
+	return s.healthy.Load()
+}
+
+// startHealthCheck begins periodically pinging the peer over the control
+// channel while the Session sits idle in the pool. It is a no-op if a probe
+// is already running or interval is non-positive.
+func (s *Session) startHealthCheck(interval, timeout time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.probeLock.Lock()
+	defer s.probeLock.Unlock()
+	if s.probeCancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.probeCancel = cancel
+	go s.healthCheckLoop(ctx, interval, timeout)
+}
+
+// stopHealthCheck stops idle probing; called when the Session is pulled out
+// of the pool to serve a stream, or when it is closed.
+func (s *Session) stopHealthCheck() {
+	s.probeLock.Lock()
+	defer s.probeLock.Unlock()
+	if s.probeCancel != nil {
+		s.probeCancel()
+		s.probeCancel = nil
+	}
+}
+
+func (s *Session) healthCheckLoop(ctx context.Context, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.dieCh:
+			return
+		case <-ticker.C:
+			pingSentAt := time.Now()
+			if err := s.writeFrame(cmdHeartRequest, 0, nil); err != nil {
+				s.healthy.Store(false)
+				continue
+			}
+			timer := time.NewTimer(timeout)
+			select {
+			case <-timer.C:
+				if time.Unix(0, s.lastPongAt.Load()).Before(pingSentAt) {
+					s.healthy.Store(false)
+				}
+			case <-s.dieCh:
+				timer.Stop()
+				return
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metacubex/mihomo/transport/anytls/padding"
+)
+
+// fakeConn is a minimal net.Conn that never blocks, for exercising Session
+// behavior that doesn't depend on actual wire traffic.
+type fakeConn struct{}
+
+func (fakeConn) Read([]byte) (int, error)         { return 0, net.ErrClosed }
+func (fakeConn) Write(b []byte) (int, error)      { return len(b), nil }
+func (fakeConn) Close() error                     { return nil }
+func (fakeConn) LocalAddr() net.Addr              { return fakeAddr{} }
+func (fakeConn) RemoteAddr() net.Addr             { return fakeAddr{} }
+func (fakeConn) SetDeadline(time.Time) error      { return nil }
+func (fakeConn) SetReadDeadline(time.Time) error  { return nil }
+func (fakeConn) SetWriteDeadline(time.Time) error { return nil }
+
+type fakeAddr struct{}
+
+func (fakeAddr) Network() string { return "fake" }
+func (fakeAddr) String() string  { return "fake" }
+
+func TestSession_PruneIdleStreams(t *testing.T) {
+	s := NewClientSession(fakeConn{}, new(atomic.Pointer[padding.PaddingFactory]))
+	defer s.Close()
+
+	idle := newStream(1, s)
+	idle.lastActivity.Store(time.Now().Add(-time.Minute).UnixNano())
+	fresh := newStream(2, s)
+
+	s.streamsLock.Lock()
+	s.streams[idle.id] = idle
+	s.streams[fresh.id] = fresh
+	s.streamsLock.Unlock()
+
+	closed := s.pruneIdleStreams(time.Second)
+	if closed != 1 {
+		t.Fatalf("expected 1 stream pruned, got %d", closed)
+	}
+	if !idle.closed.Load() {
+		t.Fatal("expected the idle stream to be closed")
+	}
+	if fresh.closed.Load() {
+		t.Fatal("expected the fresh stream to remain open")
+	}
+}
+
+func TestSession_PruneIdleStreams_DisabledWhenTimeoutZero(t *testing.T) {
+	s := NewClientSession(fakeConn{}, new(atomic.Pointer[padding.PaddingFactory]))
+	defer s.Close()
+
+	idle := newStream(1, s)
+	idle.lastActivity.Store(time.Now().Add(-time.Hour).UnixNano())
+	s.streamsLock.Lock()
+	s.streams[idle.id] = idle
+	s.streamsLock.Unlock()
+
+	if closed := s.pruneIdleStreams(0); closed != 0 {
+		t.Fatalf("expected pruneIdleStreams(0) to be a no-op, closed %d", closed)
+	}
+	if idle.closed.Load() {
+		t.Fatal("expected stream to remain open when DataIdleTimeout is disabled")
+	}
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// histogram is a small fixed-bucket latency/duration histogram. The last
+// bucket is implicit and catches anything >= the largest bound.
+type histogram struct {
+	bounds []time.Duration
+	counts []atomic.Int64
+}
+
+func newHistogram(bounds []time.Duration) *histogram {
+	return &histogram{bounds: bounds, counts: make([]atomic.Int64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	for i, bound := range h.bounds {
+		if d < bound {
+			h.counts[i].Add(1)
+			return
+		}
+	}
+	h.counts[len(h.counts)-1].Add(1)
+}
+
+// snapshot returns per-bucket counts, one more entry than bounds (the last
+// entry is the overflow bucket).
+func (h *histogram) snapshot() []int64 {
+	out := make([]int64, len(h.counts))
+	for i := range h.counts {
+		out[i] = h.counts[i].Load()
+	}
+	return out
+}
+
+var sessionAgeBuckets = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+}
+
+var streamOpenLatencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	200 * time.Millisecond,
+	time.Second,
+}
+
+// Stats is a fuller telemetry snapshot than PoolStats, meant for operators
+// tuning EnsureIdleSession, MaxConnectionLifetime, and related knobs.
+type Stats struct {
+	PoolStats
+
+	TotalSessionsCreated int64
+	ClosedIdle           int64
+	ClosedAge            int64
+	ClosedHealth         int64
+	ClosedExplicit       int64
+	ClosedDialFail       int64
+
+	ActiveCount int
+
+	// SessionAgeAtCloseBuckets/StreamOpenLatencyBuckets are counts per
+	// bucket in SessionAgeAtCloseBounds/StreamOpenLatencyBounds, with a
+	// trailing overflow bucket for values at or above the largest bound.
+	SessionAgeAtCloseBounds  []time.Duration
+	SessionAgeAtCloseBuckets []int64
+	StreamOpenLatencyBounds  []time.Duration
+	StreamOpenLatencyBuckets []int64
+
+	ProactiveCreateSuccess int64
+	ProactiveCreateFailure int64
+}
+
+// Stats reports a detailed snapshot of pool activity and health.
+func (c *Client) Stats() Stats {
+	c.sessionsLock.Lock()
+	totalSessions := len(c.sessions)
+	c.sessionsLock.Unlock()
+
+	pool := c.PoolStats()
+
+	return Stats{
+		PoolStats:                pool,
+		TotalSessionsCreated:     c.totalSessionsCreated.Load(),
+		ClosedIdle:               c.closedIdle.Load(),
+		ClosedAge:                c.closedAge.Load(),
+		ClosedHealth:             c.closedHealth.Load(),
+		ClosedExplicit:           c.closedExplicit.Load(),
+		ClosedDialFail:           c.closedDialFail.Load(),
+		ActiveCount:              totalSessions - pool.IdleCount,
+		SessionAgeAtCloseBounds:  sessionAgeBuckets,
+		SessionAgeAtCloseBuckets: c.sessionAgeAtClose.snapshot(),
+		StreamOpenLatencyBounds:  streamOpenLatencyBuckets,
+		StreamOpenLatencyBuckets: c.streamOpenLatency.snapshot(),
+		ProactiveCreateSuccess:   c.proactiveCreateSuccess.Load(),
+		ProactiveCreateFailure:   c.proactiveCreateFailure.Load(),
+	}
+}
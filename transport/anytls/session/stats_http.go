@@ -0,0 +1,32 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Lookup resolves a proxy name to the Client backing it, as registered by
+// whatever owns the proxy set (the outbound registry in full mihomo). It
+// returns false if name isn't an AnyTLS proxy.
+type Lookup func(name string) (*Client, bool)
+
+// StatsHandler builds the handler for the RESTful API's
+// "/proxies/{name}/anytls-stats" route, returning the named proxy's
+// Client.Stats() as JSON. The hub package is responsible for extracting
+// the "name" path parameter with its router of choice and mounting this
+// at that route; this stays router-agnostic so it doesn't have to import
+// hub's routing stack.
+func StatsHandler(lookup Lookup, name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, ok := lookup(name)
+		if !ok {
+			http.Error(w, "not an anytls proxy: "+name, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(client.Stats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+//go:build anytls_prometheus
+
+package session
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// anytlsCollector adapts one Client's Stats() into Prometheus metrics. It's
+// only compiled in with the anytls_prometheus build tag, so prometheus/
+// client_golang isn't pulled into default builds.
+type anytlsCollector struct {
+	name   string
+	client *Client
+}
+
+var (
+	idleCountDesc = prometheus.NewDesc(
+		"anytls_pool_idle_sessions", "Current idle sessions in the pool.", []string{"proxy"}, nil)
+	activeCountDesc = prometheus.NewDesc(
+		"anytls_pool_active_sessions", "Current checked-out sessions.", []string{"proxy"}, nil)
+	totalCreatedDesc = prometheus.NewDesc(
+		"anytls_sessions_created_total", "Total sessions created.", []string{"proxy"}, nil)
+	closedDesc = prometheus.NewDesc(
+		"anytls_sessions_closed_total", "Total sessions closed, by reason.", []string{"proxy", "reason"}, nil)
+	suspendedDesc = prometheus.NewDesc(
+		"anytls_pool_suspended", "1 if proactive/on-demand session creation is suspended.", []string{"proxy"}, nil)
+)
+
+func (a *anytlsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- idleCountDesc
+	ch <- activeCountDesc
+	ch <- totalCreatedDesc
+	ch <- closedDesc
+	ch <- suspendedDesc
+}
+
+func (a *anytlsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := a.client.Stats()
+
+	ch <- prometheus.MustNewConstMetric(idleCountDesc, prometheus.GaugeValue, float64(stats.IdleCount), a.name)
+	ch <- prometheus.MustNewConstMetric(activeCountDesc, prometheus.GaugeValue, float64(stats.ActiveCount), a.name)
+	ch <- prometheus.MustNewConstMetric(totalCreatedDesc, prometheus.CounterValue, float64(stats.TotalSessionsCreated), a.name)
+
+	for reason, n := range map[string]int64{
+		"idle":      stats.ClosedIdle,
+		"age":       stats.ClosedAge,
+		"health":    stats.ClosedHealth,
+		"explicit":  stats.ClosedExplicit,
+		"dial-fail": stats.ClosedDialFail,
+	} {
+		ch <- prometheus.MustNewConstMetric(closedDesc, prometheus.CounterValue, float64(n), a.name, reason)
+	}
+
+	suspended := 0.0
+	if stats.Suspended {
+		suspended = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(suspendedDesc, prometheus.GaugeValue, suspended, a.name)
+}
+
+// RegisterPrometheusCollector registers name's Client with reg so its pool
+// telemetry is exposed on reg's "/metrics" endpoint. The global hub code
+// calls this once per AnyTLS proxy when it owns a configured
+// prometheus.Registerer.
+func RegisterPrometheusCollector(reg prometheus.Registerer, name string, client *Client) error {
+	return reg.Register(&anytlsCollector{name: name, client: client})
+}
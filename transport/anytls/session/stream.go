@@ -0,0 +1,124 @@
+package session
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stream is a single logical multiplexed connection carried over a Session's
+// underlying transport.
+type Stream struct {
+	id      uint32
+	session *Session
+
+	// dieHook is invoked once the stream is torn down, either locally or by
+	// the peer, so the owning Client can release any bookkeeping it holds.
+	dieHook func()
+
+	readLock sync.Mutex
+	readCond *sync.Cond
+	readBuf  bytes.Buffer
+
+	closed       atomic.Bool
+	closeOnce    sync.Once
+	countedClose atomic.Bool
+
+	// lastActivity tracks this Stream's own read/write traffic, independent
+	// of the Session's control channel (see ClientConfig.DataIdleTimeout).
+	lastActivity atomic.Int64 // unix nano
+}
+
+func newStream(id uint32, session *Session) *Stream {
+	s := &Stream{id: id, session: session}
+	s.readCond = sync.NewCond(&s.readLock)
+	s.lastActivity.Store(time.Now().UnixNano())
+	return s
+}
+
+// LastActivity reports the most recent time this Stream read or wrote data.
+func (s *Stream) LastActivity() time.Time {
+	return time.Unix(0, s.lastActivity.Load())
+}
+
+// pushData is called from the Session's recvLoop when a cmdPSH frame for
+// this stream arrives.
+func (s *Stream) pushData(b []byte) {
+	s.lastActivity.Store(time.Now().UnixNano())
+	s.readLock.Lock()
+	s.readBuf.Write(b)
+	s.readCond.Signal()
+	s.readLock.Unlock()
+}
+
+func (s *Stream) Read(b []byte) (int, error) {
+	s.readLock.Lock()
+	defer s.readLock.Unlock()
+	for s.readBuf.Len() == 0 && !s.closed.Load() {
+		s.readCond.Wait()
+	}
+	if s.readBuf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return s.readBuf.Read(b)
+}
+
+func (s *Stream) Write(b []byte) (int, error) {
+	if s.closed.Load() {
+		return 0, io.ErrClosedPipe
+	}
+	// Block rather than error out while the underlying Session is migrating
+	// to a new connection.
+	s.session.waitIfMigrating()
+	if err := s.session.writeFrame(cmdPSH, s.id, b); err != nil {
+		return 0, err
+	}
+	s.lastActivity.Store(time.Now().UnixNano())
+	return len(b), nil
+}
+
+// Close tears down the stream locally and notifies the peer.
+func (s *Stream) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.session.streamsLock.Lock()
+		delete(s.session.streams, s.id)
+		s.session.streamsLock.Unlock()
+
+		// Block rather than send the FIN to a connection that's about to be
+		// torn down by an in-progress migration.
+		s.session.waitIfMigrating()
+		err = s.session.writeFrame(cmdFIN, s.id, nil)
+		s.closeFromPeer()
+	})
+	return err
+}
+
+// closeFromPeer unblocks any pending Read and marks the stream closed
+// without re-sending a cmdFIN (used when the peer or the Session closes
+// first). The first call also runs dieHook.
+func (s *Stream) closeFromPeer() {
+	s.readLock.Lock()
+	s.closed.Store(true)
+	s.readCond.Broadcast()
+	s.readLock.Unlock()
+
+	if s.countedClose.CompareAndSwap(false, true) {
+		s.session.decrementStreamCount()
+		if s.dieHook != nil {
+			s.dieHook()
+		}
+	}
+}
+
+func (s *Stream) LocalAddr() net.Addr  { return s.session.conn().LocalAddr() }
+func (s *Stream) RemoteAddr() net.Addr { return s.session.conn().RemoteAddr() }
+
+// The deadline setters are no-ops: per-stream deadlines are not supported,
+// and nothing is forwarded to the Session's underlying connection.
+func (s *Stream) SetDeadline(t time.Time) error      { return nil }
+func (s *Stream) SetReadDeadline(t time.Time) error  { return nil }
+func (s *Stream) SetWriteDeadline(t time.Time) error { return nil }